@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// AdminServer exposes the REST control API and WebSocket log/event streams
+// used by monitorctl and any other operator tooling.
+type AdminServer struct {
+	supervisor *Supervisor
+	upgrader   websocket.Upgrader
+}
+
+func newAdminServer(s *Supervisor) *AdminServer {
+	return &AdminServer{
+		supervisor: s,
+		upgrader:   websocket.Upgrader{},
+	}
+}
+
+func (a *AdminServer) router() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/api/status", a.handleStatus).Methods(http.MethodGet)
+	r.HandleFunc("/api/programs/{name}/start", a.handleControl(func(name string) error { return a.supervisor.Start(name) })).Methods(http.MethodPost)
+	r.HandleFunc("/api/programs/{name}/stop", a.handleControl(func(name string) error { return a.supervisor.Stop(name) })).Methods(http.MethodPost)
+	r.HandleFunc("/api/programs/{name}/restart", a.handleControl(func(name string) error { return a.supervisor.Restart(name) })).Methods(http.MethodPost)
+	r.HandleFunc("/api/shutdown", a.handleShutdown).Methods(http.MethodPost)
+	r.HandleFunc("/api/logs/{name}", a.handleLogs).Methods(http.MethodGet)
+	r.HandleFunc("/ws/log/{name}", a.handleWSLog)
+	r.HandleFunc("/ws/events", a.handleWSEvents)
+	r.Handle("/metrics", a.handleMetrics())
+	return r
+}
+
+// ListenAndServe starts the admin HTTP server. It blocks until the server
+// stops or fails.
+func (a *AdminServer) ListenAndServe(addr string) error {
+	slog.Info("admin API listening", "event", "admin_listen", "addr", addr)
+	return http.ListenAndServe(addr, a.router())
+}
+
+// handleMetrics refreshes the point-in-time gauges and then hands off to
+// the standard Prometheus text exposition handler.
+func (a *AdminServer) handleMetrics() http.Handler {
+	next := promhttp.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.supervisor.RefreshMetrics()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *AdminServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.supervisor.StatusAll())
+}
+
+func (a *AdminServer) handleControl(action func(name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		if err := action(name); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, a.status(name))
+	}
+}
+
+func (a *AdminServer) status(name string) Status {
+	st, _ := a.supervisor.Status(name)
+	return st
+}
+
+func (a *AdminServer) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "shutting down"})
+	go func() {
+		a.supervisor.StopAll()
+		slog.Info("shutdown requested via admin API", "event", "admin_shutdown")
+		os.Exit(0)
+	}()
+}
+
+func (a *AdminServer) handleLogs(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	n, _ := strconv.Atoi(r.URL.Query().Get("tail"))
+	logs, err := a.supervisor.Logs(name, n)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(logs)
+}
+
+func (a *AdminServer) handleWSLog(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	output, err := a.supervisor.Output(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	conn, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	id, history, ch := output.Subscribe()
+	defer output.Unsubscribe(id)
+
+	if len(history) > 0 {
+		if err := conn.WriteMessage(websocket.TextMessage, history); err != nil {
+			return
+		}
+	}
+	for chunk := range ch {
+		if err := conn.WriteMessage(websocket.TextMessage, chunk); err != nil {
+			return
+		}
+	}
+}
+
+func (a *AdminServer) handleWSEvents(w http.ResponseWriter, r *http.Request) {
+	conn, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	id, ch := a.supervisor.Events().Subscribe()
+	defer a.supervisor.Events().Unsubscribe(id)
+
+	for ev := range ch {
+		if err := conn.WriteJSON(ev); err != nil {
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
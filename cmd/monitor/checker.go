@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Checker is one health-check strategy. Implementations are registered by
+// type name in checkerFactories so new strategies can be added without
+// touching the HealthMonitor that schedules them.
+type Checker interface {
+	Check(ctx context.Context) error
+	Name() string
+}
+
+// checkerFactory builds a Checker for a program. pid returns the program's
+// current PID (0 if not running); only the process checker uses it. output
+// is the program's captured stdout/stderr stream; only the exec checker
+// uses it, to fold check output into the same log a client tails.
+type checkerFactory func(p Program, cfg CheckConfig, pid func() int, output *Output) (Checker, error)
+
+var checkerFactories = map[string]checkerFactory{
+	"http":    newHTTPChecker,
+	"tcp":     newTCPChecker,
+	"exec":    newExecChecker,
+	"grpc":    newGRPCChecker,
+	"process": newProcessChecker,
+}
+
+func newChecker(p Program, cfg CheckConfig, pid func() int, output *Output) (Checker, error) {
+	factory, ok := checkerFactories[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown health check type %q", cfg.Type)
+	}
+	return factory(p, cfg, pid, output)
+}
+
+// httpChecker performs an HTTP request and considers the program healthy
+// if the status code is one of ExpectedStatus and, when set, BodyRegex
+// matches the response body.
+type httpChecker struct {
+	cfg    CheckConfig
+	client *http.Client
+	body   *regexp.Regexp
+}
+
+func newHTTPChecker(_ Program, cfg CheckConfig, _ func() int, _ *Output) (Checker, error) {
+	c := &httpChecker{cfg: cfg, client: &http.Client{}}
+	if cfg.BodyRegex != "" {
+		re, err := regexp.Compile(cfg.BodyRegex)
+		if err != nil {
+			return nil, fmt.Errorf("http check: invalid body_regex: %w", err)
+		}
+		c.body = re
+	}
+	return c, nil
+}
+
+func (c *httpChecker) Name() string { return "http" }
+
+func (c *httpChecker) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, c.cfg.Method, c.cfg.URL, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	ok := false
+	for _, s := range c.cfg.ExpectedStatus {
+		if resp.StatusCode == s {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if c.body != nil {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if !c.body.Match(data) {
+			return fmt.Errorf("response body did not match %q", c.cfg.BodyRegex)
+		}
+	}
+	return nil
+}
+
+// tcpChecker is healthy if it can open a TCP connection to Address.
+type tcpChecker struct {
+	address string
+}
+
+func newTCPChecker(_ Program, cfg CheckConfig, _ func() int, _ *Output) (Checker, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("tcp check: address is required")
+	}
+	return &tcpChecker{address: cfg.Address}, nil
+}
+
+func (c *tcpChecker) Name() string { return "tcp" }
+
+func (c *tcpChecker) Check(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// execChecker is healthy if the configured command exits 0. Its combined
+// stdout/stderr is captured into the program's Output so `monitorctl tail`
+// shows check failures alongside the program's own logs.
+type execChecker struct {
+	command string
+	args    []string
+	output  *Output
+}
+
+func newExecChecker(_ Program, cfg CheckConfig, _ func() int, output *Output) (Checker, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("exec check: command is required")
+	}
+	return &execChecker{command: cfg.Command, args: cfg.Args, output: output}, nil
+}
+
+func (c *execChecker) Name() string { return "exec" }
+
+func (c *execChecker) Check(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, c.command, c.args...)
+	if c.output != nil {
+		cmd.Stdout = c.output
+		cmd.Stderr = c.output
+	}
+	return cmd.Run()
+}
+
+// grpcChecker probes a service via the standard gRPC health checking
+// protocol (grpc.health.v1.Health/Check).
+type grpcChecker struct {
+	address string
+	service string
+}
+
+func newGRPCChecker(_ Program, cfg CheckConfig, _ func() int, _ *Output) (Checker, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("grpc check: address is required")
+	}
+	return &grpcChecker{address: cfg.Address, service: cfg.Service}, nil
+}
+
+func (c *grpcChecker) Name() string { return "grpc" }
+
+func (c *grpcChecker) Check(ctx context.Context) error {
+	conn, err := grpc.DialContext(ctx, c.address, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: c.service})
+	if err != nil {
+		return err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("service %q is %s", c.service, resp.Status)
+	}
+	return nil
+}
+
+// processChecker is healthy as long as the program's own PID is alive. It
+// does not make a network call; the running pid is supplied at Check time.
+type processChecker struct {
+	pid func() int
+}
+
+func newProcessChecker(_ Program, _ CheckConfig, pid func() int, _ *Output) (Checker, error) {
+	return &processChecker{pid: pid}, nil
+}
+
+func (c *processChecker) Name() string { return "process" }
+
+func (c *processChecker) Check(ctx context.Context) error {
+	pid := c.pid()
+	if pid <= 0 {
+		return fmt.Errorf("process not running")
+	}
+	if err := syscall.Kill(pid, 0); err != nil {
+		return fmt.Errorf("pid %d not alive: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Program describes a single child process managed by the Supervisor.
+type Program struct {
+	Name         string            `yaml:"name"`
+	Command      string            `yaml:"command"`
+	Args         []string          `yaml:"args"`
+	Env          map[string]string `yaml:"env"`
+	WorkingDir   string            `yaml:"working_dir"`
+	AutoStart    bool              `yaml:"autostart"`
+	AutoRestart  string            `yaml:"autorestart"` // always | never | on-failure
+	StartRetries int               `yaml:"start_retries"`
+	StartSeconds int               `yaml:"start_seconds"`
+	StopSignal   string            `yaml:"stop_signal"` // TERM | KILL | INT | HUP | QUIT
+	StopTimeout  int               `yaml:"stop_timeout"`
+	HealthCheck  *CheckConfig      `yaml:"health_check"`
+}
+
+// CheckConfig configures one of the registered Checker strategies
+// (http, tcp, exec, grpc, process) for a Program's health check.
+type CheckConfig struct {
+	Type             string `yaml:"type"` // http | tcp | exec | grpc | process
+	TimeoutSeconds   int    `yaml:"timeout_seconds"`
+	IntervalSeconds  int    `yaml:"interval_seconds"`
+	FailureThreshold int    `yaml:"failure_threshold"`
+	SuccessThreshold int    `yaml:"success_threshold"`
+
+	// http
+	URL            string            `yaml:"url"`
+	Method         string            `yaml:"method"`
+	ExpectedStatus []int             `yaml:"expected_status"`
+	BodyRegex      string            `yaml:"body_regex"`
+	Headers        map[string]string `yaml:"headers"`
+
+	// tcp, grpc
+	Address string `yaml:"address"`
+
+	// exec
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+
+	// grpc
+	Service string `yaml:"service"`
+}
+
+func (c CheckConfig) applyDefaults() CheckConfig {
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = 2
+	}
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = 5
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 3
+	}
+	if c.SuccessThreshold <= 0 {
+		c.SuccessThreshold = 1
+	}
+	if c.Method == "" {
+		c.Method = http.MethodGet
+	}
+	if len(c.ExpectedStatus) == 0 {
+		c.ExpectedStatus = []int{http.StatusOK}
+	}
+	return c
+}
+
+// Config is the top-level Supervisor configuration, loaded from programs.yml.
+type Config struct {
+	Programs        []Program `yaml:"programs"`
+	IntervalSeconds int       `yaml:"interval_seconds"`
+	LogFile         string    `yaml:"log_file"`
+	LogFormat       string    `yaml:"log_format"` // json (default) | text
+	LogMaxSizeMB    int       `yaml:"log_max_size_mb"`
+	LogMaxAgeDays   int       `yaml:"log_max_age_days"`
+	AdminAddr       string    `yaml:"admin_addr"`
+}
+
+func (p Program) applyDefaults() Program {
+	if p.AutoRestart == "" {
+		p.AutoRestart = "always"
+	}
+	if p.StartRetries <= 0 {
+		p.StartRetries = 3
+	}
+	if p.StartSeconds <= 0 {
+		p.StartSeconds = 1
+	}
+	if p.StopSignal == "" {
+		p.StopSignal = "TERM"
+	}
+	if p.StopTimeout <= 0 {
+		p.StopTimeout = 10
+	}
+	return p
+}
+
+func loadConfig(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+
+	var cfg Config
+	if err := yaml.NewDecoder(f).Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if cfg.IntervalSeconds <= 0 {
+		cfg.IntervalSeconds = 5
+	}
+	if cfg.AdminAddr == "" {
+		cfg.AdminAddr = ":9001"
+	}
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = "json"
+	}
+	if len(cfg.Programs) == 0 {
+		return Config{}, fmt.Errorf("%s: no programs defined", path)
+	}
+
+	for i, p := range cfg.Programs {
+		if p.Name == "" {
+			return Config{}, fmt.Errorf("%s: program %d is missing a name", path, i)
+		}
+		cfg.Programs[i] = p.applyDefaults()
+		if hc := cfg.Programs[i].HealthCheck; hc != nil {
+			applied := hc.applyDefaults()
+			cfg.Programs[i].HealthCheck = &applied
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Event is published whenever a managed Process changes state.
+type Event struct {
+	Program string `json:"program"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s state: %s -> %s", e.Program, e.From, e.To)
+}
+
+// EventBus broadcasts Events to every subscriber, typically /ws/events
+// clients. Unlike Output it keeps no history: a subscriber only sees
+// events published after it joined.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[int]chan Event
+	nextID      int
+}
+
+func newEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]chan Event)}
+}
+
+func (b *EventBus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (b *EventBus) Subscribe() (id int, ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id = b.nextID
+	b.nextID++
+	sub := make(chan Event, 64)
+	b.subscribers[id] = sub
+	return id, sub
+}
+
+func (b *EventBus) Unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch, ok := b.subscribers[id]; ok {
+		close(ch)
+		delete(b.subscribers, id)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// HealthState is a Kubernetes-style probe outcome: a single failure moves a
+// Healthy program to Degraded rather than immediately restarting it, so a
+// transient blip doesn't kill the app; only FailureThreshold consecutive
+// failures escalate to Unhealthy.
+type HealthState int
+
+const (
+	Healthy HealthState = iota
+	Degraded
+	Unhealthy
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case Healthy:
+		return "Healthy"
+	case Degraded:
+		return "Degraded"
+	case Unhealthy:
+		return "Unhealthy"
+	default:
+		return "Unknown"
+	}
+}
+
+// HealthMonitor runs a Checker on an interval and tracks consecutive
+// failures/successes to decide when a program should be considered
+// unhealthy (and therefore restarted).
+type HealthMonitor struct {
+	program string
+	checker Checker
+	cfg     CheckConfig
+	events  *EventBus
+
+	state         HealthState
+	failStreak    int
+	successStreak int
+}
+
+func newHealthMonitor(programName string, checker Checker, cfg CheckConfig, events *EventBus) *HealthMonitor {
+	return &HealthMonitor{
+		program: programName,
+		checker: checker,
+		cfg:     cfg,
+		events:  events,
+		state:   Healthy,
+	}
+}
+
+// Run blocks, checking on cfg.IntervalSeconds, until ctx is cancelled. It
+// calls onUnhealthy after every failed check once FailureThreshold
+// consecutive failures have been reached, including checks that fail while
+// the state is already Unhealthy.
+func (h *HealthMonitor) Run(ctx context.Context, onUnhealthy func()) {
+	ticker := time.NewTicker(time.Duration(h.cfg.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.checkOnce(ctx, onUnhealthy)
+		}
+	}
+}
+
+func (h *HealthMonitor) checkOnce(ctx context.Context, onUnhealthy func()) {
+	checkCtx, cancel := context.WithTimeout(ctx, time.Duration(h.cfg.TimeoutSeconds)*time.Second)
+	start := time.Now()
+	err := h.checker.Check(checkCtx)
+	metrics.checkDurationSeconds.Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		metrics.healthChecksTotal.WithLabelValues(h.program, "failure").Inc()
+		h.successStreak = 0
+		h.failStreak++
+		slog.Warn("health check failed", "program", h.program, "event", "health_check_failed", "check", h.checker.Name(), "err", err)
+		if h.failStreak >= h.cfg.FailureThreshold {
+			h.transition(Unhealthy)
+			if onUnhealthy != nil {
+				onUnhealthy()
+			}
+		} else if h.state == Healthy {
+			h.transition(Degraded)
+		}
+		return
+	}
+
+	metrics.healthChecksTotal.WithLabelValues(h.program, "success").Inc()
+	h.failStreak = 0
+	h.successStreak++
+	if h.state != Healthy && h.successStreak >= h.cfg.SuccessThreshold {
+		h.transition(Healthy)
+	}
+}
+
+func (h *HealthMonitor) transition(to HealthState) {
+	if h.state == to {
+		return
+	}
+	from := h.state
+	h.state = to
+	ev := Event{Program: h.program, From: from.String(), To: to.String()}
+	slog.Info(ev.String(), "program", h.program, "event", "health_state_change", "state_from", ev.From, "state_to", ev.To)
+	if h.events != nil {
+		h.events.Publish(ev)
+	}
+}
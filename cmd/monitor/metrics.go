@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics holds the Prometheus collectors exposed on the admin server's
+// /metrics endpoint.
+var metrics = struct {
+	healthChecksTotal    *prometheus.CounterVec
+	restartsTotal        *prometheus.CounterVec
+	programUp            *prometheus.GaugeVec
+	programUptimeSeconds *prometheus.GaugeVec
+	programLastExitCode  *prometheus.GaugeVec
+	checkDurationSeconds prometheus.Histogram
+}{
+	healthChecksTotal: promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_health_checks_total",
+		Help: "Total number of health checks run, by program and result.",
+	}, []string{"program", "result"}),
+
+	restartsTotal: promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "monitor_restarts_total",
+		Help: "Total number of program restarts, by program and reason.",
+	}, []string{"program", "reason"}),
+
+	programUp: promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_program_up",
+		Help: "Whether the program is currently running (1) or not (0).",
+	}, []string{"program"}),
+
+	programUptimeSeconds: promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_program_uptime_seconds",
+		Help: "Seconds since the program's current process was started.",
+	}, []string{"program"}),
+
+	programLastExitCode: promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "monitor_program_last_exit_code",
+		Help: "Exit code of the program's most recently exited process.",
+	}, []string{"program"}),
+
+	checkDurationSeconds: promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "monitor_check_duration_seconds",
+		Help: "Duration of health checks across all programs and check types.",
+	}),
+}
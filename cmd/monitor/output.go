@@ -0,0 +1,70 @@
+package main
+
+import "sync"
+
+// outputHistoryBytes bounds how much of a program's combined stdout/stderr
+// is kept in memory for late-joining /ws/log subscribers.
+const outputHistoryBytes = 64 * 1024
+
+// Output is an io.Writer that fans a child process's combined stdout/stderr
+// out to any number of subscribers (e.g. WebSocket clients), while keeping
+// a ring buffer of the last outputHistoryBytes so a client that connects
+// after the process has already produced output still gets recent context.
+type Output struct {
+	mu          sync.Mutex
+	history     []byte
+	subscribers map[int]chan []byte
+	nextID      int
+}
+
+func newOutput() *Output {
+	return &Output{
+		subscribers: make(map[int]chan []byte),
+	}
+}
+
+// Write implements io.Writer. It never blocks on a slow subscriber: if a
+// subscriber's channel is full, the chunk is dropped for that subscriber
+// rather than stalling the child process's output.
+func (o *Output) Write(p []byte) (int, error) {
+	chunk := append([]byte(nil), p...)
+
+	o.mu.Lock()
+	o.history = append(o.history, chunk...)
+	if len(o.history) > outputHistoryBytes {
+		o.history = o.history[len(o.history)-outputHistoryBytes:]
+	}
+	for _, ch := range o.subscribers {
+		select {
+		case ch <- chunk:
+		default:
+		}
+	}
+	o.mu.Unlock()
+
+	return len(p), nil
+}
+
+// Subscribe registers a new listener and returns a snapshot of the recent
+// history plus a channel that receives every chunk written from now on.
+// The caller must call Unsubscribe when done.
+func (o *Output) Subscribe() (id int, history []byte, ch <-chan []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	id = o.nextID
+	o.nextID++
+	sub := make(chan []byte, 64)
+	o.subscribers[id] = sub
+
+	return id, append([]byte(nil), o.history...), sub
+}
+
+func (o *Output) Unsubscribe(id int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if ch, ok := o.subscribers[id]; ok {
+		close(ch)
+		delete(o.subscribers, id)
+	}
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// State is a position in the Process state machine:
+//
+//	Stopped -> Starting -> Running -> Backoff -> Starting ...
+//	                          \-> Exited
+//	Starting -> Fatal (start_retries exhausted)
+type State int
+
+const (
+	Stopped State = iota
+	Starting
+	Running
+	Backoff
+	Fatal
+	Exited
+)
+
+func (s State) String() string {
+	switch s {
+	case Stopped:
+		return "Stopped"
+	case Starting:
+		return "Starting"
+	case Running:
+		return "Running"
+	case Backoff:
+		return "Backoff"
+	case Fatal:
+		return "Fatal"
+	case Exited:
+		return "Exited"
+	default:
+		return "Unknown"
+	}
+}
+
+// Process owns the exec.Cmd for a single Program and drives it through the
+// Process state machine, restarting it according to the program's
+// autorestart policy.
+type Process struct {
+	program Program
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd
+	state     State
+	retryLeft int
+	startedAt time.Time
+	pid       int
+	lastExit  int
+	restarts  int
+
+	// restartReason labels the next monitor_restarts_total increment; reset
+	// to "crash" once consumed by run()'s backoff transition.
+	restartReason string
+
+	output *Output
+	events *EventBus
+
+	healthCancel context.CancelFunc
+	waitDone     chan struct{} // closed by waitForExit once cmd.Wait() returns
+
+	running bool          // true while a run() goroutine owns this Process
+	stopped chan struct{} // closed to ask the restart loop to give up
+	runDone chan struct{} // closed by run() when it returns, releasing ownership
+}
+
+func newProcess(p Program, events *EventBus) *Process {
+	runDone := make(chan struct{})
+	close(runDone) // no run() goroutine owns this Process yet
+	return &Process{
+		program:       p,
+		state:         Stopped,
+		retryLeft:     p.StartRetries,
+		restartReason: "crash",
+		output:        newOutput(),
+		events:        events,
+		stopped:       make(chan struct{}),
+		runDone:       runDone,
+	}
+}
+
+func (p *Process) setState(s State) {
+	from := p.state
+	p.state = s
+	if from != s {
+		ev := Event{Program: p.program.Name, From: from.String(), To: s.String()}
+		slog.Info(ev.String(), "program", p.program.Name, "event", "state_change", "state_from", ev.From, "state_to", ev.To, "pid", p.pid)
+		if p.events != nil {
+			p.events.Publish(ev)
+		}
+		if s == Running {
+			metrics.programUp.WithLabelValues(p.program.Name).Set(1)
+		} else {
+			metrics.programUp.WithLabelValues(p.program.Name).Set(0)
+		}
+	}
+}
+
+// tryStart claims this Process for a new run() goroutine, atomically
+// resetting the stopped and runDone channels and the retry budget under
+// the same lock run()/spawn()/stop() use to read them. It returns false
+// if a run() goroutine is already live (e.g. sleeping in the backoff
+// loop), so callers must not launch a second one on top of it.
+func (p *Process) tryStart() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return false
+	}
+	p.running = true
+	p.retryLeft = p.program.StartRetries
+	p.stopped = make(chan struct{})
+	p.runDone = make(chan struct{})
+	return true
+}
+
+// refreshMetrics updates the gauges that reflect point-in-time state
+// (uptime, last exit code) right before a /metrics scrape.
+func (p *Process) refreshMetrics() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var uptime float64
+	if p.state == Running || p.state == Starting {
+		uptime = time.Since(p.startedAt).Seconds()
+	}
+	metrics.programUptimeSeconds.WithLabelValues(p.program.Name).Set(uptime)
+	metrics.programLastExitCode.WithLabelValues(p.program.Name).Set(float64(p.lastExit))
+}
+
+// run starts the child process and supervises it until stop() is called,
+// the autorestart policy leaves it Exited, or start_retries are exhausted
+// and it goes Fatal. It is meant to be launched in its own goroutine, one per Process, and
+// only after a successful tryStart() claims ownership.
+func (p *Process) run() {
+	defer func() {
+		p.mu.Lock()
+		p.running = false
+		close(p.runDone)
+		p.mu.Unlock()
+	}()
+
+	for {
+		if !p.spawn() {
+			return
+		}
+
+		p.mu.Lock()
+		started := p.cmd != nil
+		p.mu.Unlock()
+
+		var exited error
+		if started {
+			exited = p.waitForExit()
+		} else {
+			exited = fmt.Errorf("%s: failed to start", p.program.Name)
+		}
+		select {
+		case <-p.stopped:
+			return
+		default:
+		}
+
+		if p.program.AutoRestart == "never" {
+			p.mu.Lock()
+			p.setState(Exited)
+			p.mu.Unlock()
+			return
+		}
+		if p.program.AutoRestart == "on-failure" && exited == nil {
+			p.mu.Lock()
+			p.setState(Exited)
+			p.mu.Unlock()
+			return
+		}
+
+		p.mu.Lock()
+		uptime := time.Since(p.startedAt)
+		if uptime < time.Duration(p.program.StartSeconds)*time.Second {
+			p.retryLeft--
+			if p.retryLeft < 0 {
+				p.setState(Fatal)
+				p.mu.Unlock()
+				slog.Error("giving up after too many quick exits", "program", p.program.Name, "event", "restart_fatal", "start_retries", p.program.StartRetries)
+				return
+			}
+		} else {
+			p.retryLeft = p.program.StartRetries
+		}
+		p.restarts++
+		reason := p.restartReason
+		p.restartReason = "crash"
+		metrics.restartsTotal.WithLabelValues(p.program.Name, reason).Inc()
+		p.setState(Backoff)
+		p.mu.Unlock()
+
+		time.Sleep(time.Second)
+	}
+}
+
+// spawn starts the child process. It returns false if stop() was called
+// before the process could be started.
+func (p *Process) spawn() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.stopped:
+		return false
+	default:
+	}
+
+	cmd := exec.Command(p.program.Command, p.program.Args...)
+	cmd.Dir = p.program.WorkingDir
+	cmd.Env = append(os.Environ(), envSlice(p.program.Env)...)
+	cmd.Stdout = io.MultiWriter(os.Stdout, p.output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, p.output)
+	// Run the child as its own process group leader so stop()/terminate()
+	// can signal it and any children it spawns together.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		slog.Error("failed to start program", "program", p.program.Name, "event", "start_failed", "err", err)
+		// Leave p.cmd nil so run() skips waitForExit (which would panic on
+		// a nil *exec.Cmd) and instead counts this as an immediate exit
+		// against the retry budget.
+		p.startedAt = time.Now()
+		return true
+	}
+
+	p.cmd = cmd
+	p.pid = cmd.Process.Pid
+	p.waitDone = make(chan struct{})
+	p.startedAt = time.Now()
+	p.setState(Starting)
+	slog.Info("program started", "program", p.program.Name, "event", "started", "pid", p.pid)
+
+	go func() {
+		time.Sleep(time.Duration(p.program.StartSeconds) * time.Second)
+		p.mu.Lock()
+		becameRunning := false
+		if p.state == Starting {
+			select {
+			case <-p.stopped:
+				// stop() ran during the start_seconds window; leave the
+				// state alone and don't start a health monitor against a
+				// process that's already being torn down.
+			default:
+				p.setState(Running)
+				becameRunning = true
+			}
+		}
+		p.mu.Unlock()
+
+		if becameRunning && p.program.HealthCheck != nil {
+			p.startHealthMonitor()
+		}
+	}()
+
+	return true
+}
+
+// startHealthMonitor builds the Checker configured for this program and
+// runs it until the current child process exits or is stopped.
+func (p *Process) startHealthMonitor() {
+	checker, err := newChecker(p.program, *p.program.HealthCheck, func() int {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.cmd == nil {
+			return 0
+		}
+		return p.pid
+	}, p.output)
+	if err != nil {
+		slog.Error("cannot start health check", "program", p.program.Name, "event", "health_check_setup_failed", "err", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.healthCancel = cancel
+	p.mu.Unlock()
+
+	monitor := newHealthMonitor(p.program.Name, checker, *p.program.HealthCheck, p.events)
+	monitor.Run(ctx, p.killForUnhealthy)
+}
+
+// killForUnhealthy gracefully stops the child so the restart loop in run()
+// picks it back up according to the program's autorestart policy.
+func (p *Process) killForUnhealthy() {
+	slog.Warn("program unhealthy, restarting", "program", p.program.Name, "event", "unhealthy_restart")
+	p.mu.Lock()
+	p.restartReason = "unhealthy"
+	p.mu.Unlock()
+	p.terminate()
+}
+
+// terminate sends the program's configured stop_signal to the whole
+// process group and waits up to stop_timeout for it to exit, escalating
+// to SIGKILL if it doesn't.
+func (p *Process) terminate() {
+	p.mu.Lock()
+	cmd := p.cmd
+	done := p.waitDone
+	p.mu.Unlock()
+
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+
+	pgid := cmd.Process.Pid
+	sig := parseStopSignal(p.program.StopSignal)
+	if err := syscall.Kill(-pgid, sig); err != nil {
+		slog.Error("failed to signal process group", "program", p.program.Name, "event", "signal_failed", "err", err)
+	}
+
+	if done == nil {
+		return
+	}
+
+	timeout := time.Duration(p.program.StopTimeout) * time.Second
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("program did not stop within timeout, sending SIGKILL", "program", p.program.Name, "event", "stop_timeout", "timeout", timeout)
+		_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		<-done
+	}
+}
+
+// waitForExit blocks until the child exits and records its outcome.
+func (p *Process) waitForExit() error {
+	p.mu.Lock()
+	cmd := p.cmd
+	p.mu.Unlock()
+
+	err := cmd.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.healthCancel != nil {
+		p.healthCancel()
+		p.healthCancel = nil
+	}
+	if cmd.ProcessState != nil {
+		p.lastExit = cmd.ProcessState.ExitCode()
+	}
+	p.cmd = nil
+	if p.waitDone != nil {
+		close(p.waitDone)
+		p.waitDone = nil
+	}
+	if err != nil {
+		slog.Warn("program exited with error", "program", p.program.Name, "event", "exited", "err", err)
+	} else {
+		slog.Info("program exited normally", "program", p.program.Name, "event", "exited")
+	}
+	return err
+}
+
+func (p *Process) stop() {
+	p.mu.Lock()
+	select {
+	case <-p.stopped:
+	default:
+		close(p.stopped)
+	}
+	p.mu.Unlock()
+
+	p.terminate()
+
+	// Wait for run() to actually relinquish ownership before returning, so
+	// a caller that immediately follows stop() with tryStart() (e.g.
+	// Supervisor.Restart) can't race the deferred p.running = false and be
+	// wrongly told the process is already running.
+	p.mu.Lock()
+	done := p.runDone
+	p.mu.Unlock()
+	<-done
+
+	p.mu.Lock()
+	p.setState(Stopped)
+	p.mu.Unlock()
+}
+
+// Status is the externally visible snapshot of a Process, returned by the
+// Supervisor's status API.
+type Status struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Pid      int    `json:"pid"`
+	Uptime   int64  `json:"uptime_seconds"`
+	Restarts int    `json:"restarts"`
+	LastExit int    `json:"last_exit_code"`
+}
+
+func (p *Process) status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var uptime int64
+	if p.state == Running || p.state == Starting {
+		uptime = int64(time.Since(p.startedAt).Seconds())
+	}
+
+	return Status{
+		Name:     p.program.Name,
+		State:    p.state.String(),
+		Pid:      p.pid,
+		Uptime:   uptime,
+		Restarts: p.restarts,
+		LastExit: p.lastExit,
+	}
+}
+
+func envSlice(env map[string]string) []string {
+	out := make([]string, 0, len(env))
+	for k, v := range env {
+		out = append(out, fmt.Sprintf("%s=%s", k, v))
+	}
+	return out
+}
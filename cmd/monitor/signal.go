@@ -0,0 +1,20 @@
+package main
+
+import "syscall"
+
+func parseStopSignal(name string) syscall.Signal {
+	switch name {
+	case "KILL":
+		return syscall.SIGKILL
+	case "INT":
+		return syscall.SIGINT
+	case "HUP":
+		return syscall.SIGHUP
+	case "QUIT":
+		return syscall.SIGQUIT
+	case "TERM", "":
+		return syscall.SIGTERM
+	default:
+		return syscall.SIGTERM
+	}
+}
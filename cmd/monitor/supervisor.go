@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// Supervisor owns a set of Processes, keyed by program name, and provides
+// the Start/Stop/Restart/Status operations used by both the admin API and
+// the reload-on-SIGHUP logic.
+type Supervisor struct {
+	configPath string
+	events     *EventBus
+
+	mu        sync.Mutex
+	processes map[string]*Process
+}
+
+func newSupervisor(configPath string) *Supervisor {
+	return &Supervisor{
+		configPath: configPath,
+		events:     newEventBus(),
+		processes:  make(map[string]*Process),
+	}
+}
+
+// start registers every program in cfg and launches those marked autostart.
+func (s *Supervisor) start(cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range cfg.Programs {
+		proc := newProcess(p, s.events)
+		s.processes[p.Name] = proc
+		if p.AutoStart && proc.tryStart() {
+			go proc.run()
+		}
+	}
+}
+
+func (s *Supervisor) get(name string) (*Process, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	proc, ok := s.processes[name]
+	if !ok {
+		return nil, fmt.Errorf("no such program: %s", name)
+	}
+	return proc, nil
+}
+
+func (s *Supervisor) Start(name string) error {
+	proc, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	if !proc.tryStart() {
+		return fmt.Errorf("%s already running", name)
+	}
+	go proc.run()
+	return nil
+}
+
+func (s *Supervisor) Stop(name string) error {
+	proc, err := s.get(name)
+	if err != nil {
+		return err
+	}
+	proc.stop()
+	return nil
+}
+
+func (s *Supervisor) Restart(name string) error {
+	if err := s.Stop(name); err != nil {
+		return err
+	}
+	return s.Start(name)
+}
+
+func (s *Supervisor) Status(name string) (Status, error) {
+	proc, err := s.get(name)
+	if err != nil {
+		return Status{}, err
+	}
+	return proc.status(), nil
+}
+
+// Output returns the given program's live output stream, for /ws/log.
+func (s *Supervisor) Output(name string) (*Output, error) {
+	proc, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+	return proc.output, nil
+}
+
+// Events returns the supervisor-wide event bus, for /ws/events.
+func (s *Supervisor) Events() *EventBus {
+	return s.events
+}
+
+// Logs returns up to the last n bytes of captured stdout/stderr for name.
+func (s *Supervisor) Logs(name string, n int) ([]byte, error) {
+	proc, err := s.get(name)
+	if err != nil {
+		return nil, err
+	}
+	id, history, _ := proc.output.Subscribe()
+	defer proc.output.Unsubscribe(id)
+	if n > 0 && n < len(history) {
+		history = history[len(history)-n:]
+	}
+	return history, nil
+}
+
+func (s *Supervisor) StatusAll() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Status, 0, len(s.processes))
+	for _, proc := range s.processes {
+		out = append(out, proc.status())
+	}
+	return out
+}
+
+// RefreshMetrics updates the point-in-time Prometheus gauges (uptime, last
+// exit code) for every managed program. Call this before serving /metrics.
+func (s *Supervisor) RefreshMetrics() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, proc := range s.processes {
+		proc.refreshMetrics()
+	}
+}
+
+func (s *Supervisor) StopAll() {
+	s.mu.Lock()
+	procs := make([]*Process, 0, len(s.processes))
+	for _, proc := range s.processes {
+		procs = append(procs, proc)
+	}
+	s.mu.Unlock()
+
+	for _, proc := range procs {
+		proc.stop()
+	}
+}
+
+// Reload re-reads programs.yml and reconciles the running set: new programs
+// are started (if autostart), removed programs are stopped, and programs
+// whose definition changed are stopped and, if autostart, started again
+// with the new definition.
+func (s *Supervisor) Reload() error {
+	cfg, err := loadConfig(s.configPath)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(cfg.Programs))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range cfg.Programs {
+		seen[p.Name] = true
+		existing, ok := s.processes[p.Name]
+		if !ok {
+			proc := newProcess(p, s.events)
+			s.processes[p.Name] = proc
+			if p.AutoStart && proc.tryStart() {
+				go proc.run()
+			}
+			slog.Info("reload added program", "program", p.Name, "event", "reload_added")
+			continue
+		}
+		if !reflect.DeepEqual(existing.program, p) {
+			slog.Info("reload restarting changed program", "program", p.Name, "event", "reload_changed")
+			existing.stop()
+			proc := newProcess(p, s.events)
+			s.processes[p.Name] = proc
+			if p.AutoStart && proc.tryStart() {
+				go proc.run()
+			}
+		}
+	}
+
+	for name, proc := range s.processes {
+		if !seen[name] {
+			slog.Info("reload removed program", "program", name, "event", "reload_removed")
+			proc.stop()
+			delete(s.processes, name)
+		}
+	}
+
+	return nil
+}
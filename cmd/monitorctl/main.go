@@ -0,0 +1,135 @@
+// Command monitorctl is a small HTTP client for the monitor's admin API,
+// used to inspect and control managed programs from the shell.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/websocket"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: monitorctl [-addr http://host:port] <status|start|stop|restart|shutdown|tail> [name]")
+	os.Exit(2)
+}
+
+func main() {
+	args := os.Args[1:]
+	addr := os.Getenv("MONITORCTL_ADDR")
+	if addr == "" {
+		addr = "http://127.0.0.1:9001"
+	}
+	if len(args) >= 2 && args[0] == "-addr" {
+		addr = args[1]
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		usage()
+	}
+
+	cmd, rest := args[0], args[1:]
+	var err error
+	switch cmd {
+	case "status":
+		err = cmdStatus(addr)
+	case "start", "stop", "restart":
+		err = cmdControl(addr, cmd, name(rest))
+	case "shutdown":
+		err = cmdShutdown(addr)
+	case "tail":
+		err = cmdTail(addr, name(rest))
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "monitorctl:", err)
+		os.Exit(1)
+	}
+}
+
+func name(rest []string) string {
+	if len(rest) == 0 {
+		usage()
+	}
+	return rest[0]
+}
+
+func cmdStatus(addr string) error {
+	resp, err := http.Get(addr + "/api/status")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var statuses []Status
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return err
+	}
+
+	fmt.Printf("%-20s %-10s %-8s %-10s %-8s\n", "NAME", "STATE", "PID", "UPTIME(s)", "RESTARTS")
+	for _, s := range statuses {
+		fmt.Printf("%-20s %-10s %-8d %-10d %-8d\n", s.Name, s.State, s.Pid, s.Uptime, s.Restarts)
+	}
+	return nil
+}
+
+// Status mirrors the monitor's admin API response shape.
+type Status struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Pid      int    `json:"pid"`
+	Uptime   int64  `json:"uptime_seconds"`
+	Restarts int    `json:"restarts"`
+	LastExit int    `json:"last_exit_code"`
+}
+
+func cmdControl(addr, action, name string) error {
+	resp, err := http.Post(fmt.Sprintf("%s/api/programs/%s/%s", addr, name, action), "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, body)
+	}
+	fmt.Printf("%s: %s ok\n", name, action)
+	return nil
+}
+
+func cmdShutdown(addr string) error {
+	resp, err := http.Post(addr+"/api/shutdown", "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	fmt.Println("shutdown requested")
+	return nil
+}
+
+func cmdTail(addr, name string) error {
+	wsAddr := "ws" + addr[len("http"):] + "/ws/log/" + name
+	conn, _, err := websocket.DefaultDialer.Dial(wsAddr, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return nil
+		}
+		out.Write(msg)
+		out.Flush()
+	}
+}